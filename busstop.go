@@ -0,0 +1,117 @@
+// Package busstop answers "how long until the next bus" style queries
+// against a pluggable transit provider/provider.go#Provider. It started
+// life as a NexTrip-only one-shot CLI and is now a reusable library
+// consumed by cmd/busstop-cli and cmd/busstop-server.
+package busstop
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/JoseBeto/BusStop/provider"
+)
+
+// GetDepartures resolves busRoute/busStop/direction against p and
+// returns up to count of its next departures, earliest first, excluding
+// any a rider couldn't reach in walkMinutes. count <= 0 means no limit.
+func GetDepartures(ctx context.Context, p provider.Provider, busRoute string, busStop string, direction string, count int, walkMinutes int) ([]provider.Departure, error) {
+	routeID, directionID, placeCode, err := resolveStop(ctx, p, busRoute, busStop, direction)
+	if err != nil {
+		return nil, err
+	}
+
+	routeDepartures, err := p.Departures(ctx, routeID, directionID, placeCode)
+	if err != nil {
+		return nil, err
+	}
+
+	earliestCatchable := time.Now().Add(time.Duration(walkMinutes) * time.Minute).Unix()
+
+	departures := make([]provider.Departure, 0, len(routeDepartures.Departures))
+	for _, d := range routeDepartures.Departures {
+		if d.Departure_time < earliestCatchable {
+			continue
+		}
+		departures = append(departures, d)
+		if count > 0 && len(departures) == count {
+			break
+		}
+	}
+	return departures, nil
+}
+
+// resolveStop turns a human-entered route label, stop description, and
+// direction into the route_id/direction_id/place_code a Provider's
+// Departures method needs.
+func resolveStop(ctx context.Context, p provider.Provider, busRoute string, busStop string, direction string) (routeID string, directionID int, placeCode string, err error) {
+	routes, err := p.Routes(ctx)
+	if err != nil {
+		return "", 0, "", errors.New("Error retrieving routes: " + err.Error())
+	}
+
+	// Loop through routes and retrieve route that the user has requested
+	var requestedRoute provider.Route
+	for _, route := range routes {
+		if route.Route_label == busRoute {
+			requestedRoute = route
+			break
+		}
+	}
+
+	// Return error if route not found
+	if requestedRoute.Route_label == "" {
+		return "", 0, "", errors.New("Route not found")
+	}
+
+	// Get direction_id of route given a direction
+	directionID, err = getBusDirectionID(ctx, p, requestedRoute.Route_id, direction)
+	if err != nil {
+		return "", 0, "", errors.New("Error getting bus direction ID: " + err.Error())
+	}
+
+	// Get place_code of route given a direction_id and busStop name
+	placeCode, err = getBusStopPlaceCode(ctx, p, requestedRoute.Route_id, directionID, busStop)
+	if err != nil {
+		return "", 0, "", errors.New("Error getting bus stop place code: " + err.Error())
+	}
+
+	return requestedRoute.Route_id, directionID, placeCode, nil
+}
+
+// getBusDirectionID pulls in route directions from p and returns the
+// direction_id of the first one whose name contains direction. If no
+// match is found, it returns an error.
+func getBusDirectionID(ctx context.Context, p provider.Provider, route_id string, direction string) (direction_id int, err error) {
+	directions, err := p.Directions(ctx, route_id)
+	if err != nil {
+		return
+	}
+
+	// Loop through directions until direction_name contains direction. If not found, return error
+	for _, d := range directions {
+		if strings.Contains(strings.ToLower(d.Direction_name), direction) {
+			return d.Direction_id, nil
+		}
+	}
+	return direction_id, errors.New("Route direction not found")
+}
+
+// getBusStopPlaceCode pulls in stops from p and returns the place_code
+// of the first one whose description contains busStop. If no match is
+// found, it returns an error.
+func getBusStopPlaceCode(ctx context.Context, p provider.Provider, route_id string, direction_id int, busStop string) (place_code string, err error) {
+	stops, err := p.Stops(ctx, route_id, direction_id)
+	if err != nil {
+		return
+	}
+
+	// Loop through stops until description matches busStop. If not found, return error
+	for _, stop := range stops {
+		if strings.Contains(stop.Description, busStop) {
+			return stop.Place_code, nil
+		}
+	}
+	return place_code, errors.New("Bus stop place code not found")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/JoseBeto/BusStop/provider"
+)
+
+// render formats departures for display in the requested format: text,
+// json, or table.
+func render(departures []provider.Departure, format string) (string, error) {
+	switch format {
+	case "", "text":
+		return renderText(departures), nil
+	case "json":
+		return renderJSON(departures)
+	case "table":
+		return renderTable(departures), nil
+	default:
+		return "", fmt.Errorf("unknown format %q: use text, json, or table", format)
+	}
+}
+
+func renderText(departures []provider.Departure) string {
+	if len(departures) == 0 {
+		return "No upcoming departures"
+	}
+
+	lines := make([]string, len(departures))
+	for i, d := range departures {
+		lines[i] = fmt.Sprintf("%d Minutes", int(time.Until(time.Unix(d.Departure_time, 0)).Minutes()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderJSON(departures []provider.Departure) (string, error) {
+	body, err := json.MarshalIndent(departures, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+func renderTable(departures []provider.Departure) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MINUTES\tACTUAL\tSCHEDULED\tREALTIME")
+	for _, d := range departures {
+		scheduled := "-"
+		if d.Scheduled_time != 0 {
+			scheduled = time.Unix(d.Scheduled_time, 0).Format("15:04")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%t\n",
+			int(time.Until(time.Unix(d.Departure_time, 0)).Minutes()),
+			time.Unix(d.Departure_time, 0).Format("15:04"),
+			scheduled,
+			d.Realtime,
+		)
+	}
+	w.Flush()
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,153 @@
+// Command busstop-cli is the original one-shot CLI: given a bus route,
+// stop, and direction, it prints the next departure(s).
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JoseBeto/BusStop"
+	"github.com/JoseBeto/BusStop/geo"
+	"github.com/JoseBeto/BusStop/gtfs"
+	"github.com/JoseBeto/BusStop/provider"
+)
+
+func main() {
+	providerName := flag.String("provider", "nextrip", "transit provider backend: nextrip, entur, prim, tfl, or gtfs")
+	count := flag.Int("count", 1, "number of upcoming departures to show")
+	walkMinutes := flag.Int("walk-minutes", 0, "exclude departures you couldn't walk to the stop in time for")
+	format := flag.String("format", "text", "output format: text, json, or table")
+	near := flag.String("near", "", `coordinates "lat,lon" to find the nearest stop on the route, in place of the stop argument`)
+	gtfsFeed := flag.String("gtfs-feed", "", "GTFS static feed (zip URL or local path); required for --near and for --provider gtfs")
+	gtfsRealtimeURL := flag.String("gtfs-realtime-url", "", "GTFS-Realtime TripUpdates feed URL used to live-adjust --provider gtfs departures")
+	watchMode := flag.Bool("watch", false, "keep running, printing an update only when departures change")
+	watchInterval := flag.Duration("watch-interval", 30*time.Second, "how often to re-query departures in --watch mode")
+	notifyURL := flag.String("notify-url", "", "webhook to POST the new departures to on every change in --watch mode")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	busRoute, busStop, direction, errMsg := parseArgs(flag.Args(), *near)
+	if errMsg != "" {
+		fmt.Println(errMsg)
+		os.Exit(1)
+	}
+
+	if *near != "" {
+		stop, err := nearestStop(ctx, *near, *gtfsFeed, busRoute)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		busStop = stop
+	}
+
+	p, err := newProvider(ctx, *providerName, *gtfsFeed, *gtfsRealtimeURL)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if *watchMode {
+		if err := watch(ctx, p, busRoute, busStop, direction, *count, *walkMinutes, *format, *watchInterval, *notifyURL); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	departures, err := busstop.GetDepartures(ctx, p, busRoute, busStop, direction, *count, *walkMinutes)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	out, err := render(departures, *format)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println(out)
+}
+
+// newProvider builds the Provider named by providerName. "gtfs" is
+// handled here rather than in provider.New, since it loads a static
+// feed (and optionally layers a GTFS-Realtime TripUpdates feed on top)
+// instead of just reading credentials from the environment.
+func newProvider(ctx context.Context, providerName string, gtfsFeed string, gtfsRealtimeURL string) (provider.Provider, error) {
+	if strings.ToLower(providerName) != "gtfs" {
+		return provider.New(providerName)
+	}
+
+	if gtfsFeed == "" {
+		return nil, fmt.Errorf("--provider gtfs requires --gtfs-feed")
+	}
+	static, err := gtfs.LoadStatic(ctx, gtfsFeed)
+	if err != nil {
+		return nil, fmt.Errorf("loading GTFS feed: %w", err)
+	}
+	return gtfs.Realtime{Static: static, FeedURL: gtfsRealtimeURL}, nil
+}
+
+// nearestStop resolves near ("lat,lon") to the description of the
+// closest stop on busRoute, using the GTFS feed at gtfsFeed for route
+// and stop geometry.
+func nearestStop(ctx context.Context, near string, gtfsFeed string, busRoute string) (string, error) {
+	if gtfsFeed == "" {
+		return "", fmt.Errorf("--near requires --gtfs-feed to resolve stop geometry")
+	}
+
+	lat, lon, err := parseCoordinates(near)
+	if err != nil {
+		return "", err
+	}
+
+	static, err := gtfs.LoadStatic(ctx, gtfsFeed)
+	if err != nil {
+		return "", fmt.Errorf("loading GTFS feed: %w", err)
+	}
+
+	stops, _ := geo.NewIndex(static).NearestStops(lat, lon, busRoute, 1)
+	if len(stops) == 0 {
+		return "", fmt.Errorf("no stops found on route %q near %s", busRoute, near)
+	}
+	return stops[0].Description, nil
+}
+
+func parseCoordinates(near string) (lat float64, lon float64, err error) {
+	parts := strings.Split(near, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf(`--near must be in the form "lat,lon", got %q`, near)
+	}
+
+	lat, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("--near: invalid latitude: %w", err)
+	}
+	lon, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("--near: invalid longitude: %w", err)
+	}
+	return lat, lon, nil
+}
+
+// parseArgs reads [BusRoute] [BusStop] [Direction], or, when near is
+// set, [BusRoute] [Direction] with the stop resolved via --near instead.
+func parseArgs(args []string, near string) (busRoute string, busStop string, direction string, errMsg string) {
+	if near != "" {
+		if len(args) != 2 {
+			return "", "", "", "Not enough arguments. Use: busstop-cli --near \"lat,lon\" [flags] [BusRoute] [Direction]"
+		}
+		return args[0], "", args[1], ""
+	}
+
+	if len(args) != 3 {
+		return "", "", "", "Not enough arguments. Use: busstop-cli [flags] [BusRoute] [BusStop] [Direction]"
+	}
+	return args[0], args[1], args[2], ""
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/JoseBeto/BusStop"
+	"github.com/JoseBeto/BusStop/provider"
+)
+
+// watch re-queries departures on interval and prints an update only
+// when the result changes since the last poll: it keys change-detection
+// on minutes-until-departure rather than absolute departure_time, so the
+// countdown still advances tick over tick even when the schedule itself
+// hasn't changed. On a change, it renders a diff line and, if notifyURL
+// is set, posts the new departures there as a webhook.
+func watch(ctx context.Context, p provider.Provider, busRoute, busStop, direction string, count, walkMinutes int, format string, interval time.Duration, notifyURL string) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastKey []byte
+	var lastDepartures []provider.Departure
+
+	for {
+		departures, err := busstop.GetDepartures(ctx, p, busRoute, busStop, direction, count, walkMinutes)
+		if err != nil {
+			fmt.Println(err)
+		} else {
+			key, err := minutesKey(departures)
+			if err != nil {
+				return err
+			}
+
+			if !bytes.Equal(key, lastKey) {
+				if lastKey == nil {
+					out, err := render(departures, format)
+					if err != nil {
+						return err
+					}
+					fmt.Println(out)
+				} else {
+					fmt.Println(diffLine(lastDepartures, departures))
+					if notifyURL != "" {
+						body, err := json.Marshal(departures)
+						if err != nil {
+							return err
+						}
+						notify(notifyURL, body)
+					}
+				}
+				lastKey = key
+				lastDepartures = departures
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// minutesKey renders departures' minutes-until-arrival as the
+// change-detection key, so watch notices the countdown ticking down even
+// when the underlying departure_time values are unchanged.
+func minutesKey(departures []provider.Departure) ([]byte, error) {
+	minutes := make([]int, len(departures))
+	for i, d := range departures {
+		minutes[i] = minutesUntil(d)
+	}
+	return json.Marshal(minutes)
+}
+
+// diffLine renders how the next departure changed between polls, e.g.
+// "3 min -> 2 min".
+func diffLine(before, after []provider.Departure) string {
+	if len(after) == 0 {
+		return "no more departures"
+	}
+	if len(before) == 0 {
+		return fmt.Sprintf("%d min", minutesUntil(after[0]))
+	}
+	return fmt.Sprintf("%d min -> %d min", minutesUntil(before[0]), minutesUntil(after[0]))
+}
+
+func minutesUntil(d provider.Departure) int {
+	return int(time.Until(time.Unix(d.Departure_time, 0)).Minutes())
+}
+
+// notify fires a best-effort webhook with the new departures; delivery
+// failures are logged, not returned, so a flaky webhook can't stall
+// watch mode.
+func notify(notifyURL string, body []byte) {
+	resp, err := http.Post(notifyURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		fmt.Println("notify: " + err.Error())
+		return
+	}
+	resp.Body.Close()
+}
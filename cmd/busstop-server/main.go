@@ -0,0 +1,252 @@
+// Command busstop-server exposes BusStop's route, direction, stop, and
+// departure lookups as a JSON HTTP API, in the style of the atb repo:
+// each resource embeds a "url" field pointing at the next resource down
+// the route -> direction -> stop -> departures chain, and responses are
+// cached in-process so the upstream provider isn't hit on every request.
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JoseBeto/BusStop/gtfs"
+	"github.com/JoseBeto/BusStop/provider"
+)
+
+const (
+	routesTTL     = 7 * 24 * time.Hour
+	directionsTTL = 24 * time.Hour
+	stopsTTL      = 24 * time.Hour
+	departuresTTL = time.Minute
+)
+
+// server dispatches requests to a transit Provider, caching each
+// resource type in its own ttlCache since each has its own refresh
+// cadence.
+type server struct {
+	provider   provider.Provider
+	routes     *ttlCache
+	directions *ttlCache
+	stops      *ttlCache
+	departures *ttlCache
+}
+
+func newServer(p provider.Provider) *server {
+	return &server{
+		provider:   p,
+		routes:     newTTLCache(routesTTL),
+		directions: newTTLCache(directionsTTL),
+		stops:      newTTLCache(stopsTTL),
+		departures: newTTLCache(departuresTTL),
+	}
+}
+
+type routeResource struct {
+	provider.Route
+	URL string `json:"url"`
+}
+
+type directionResource struct {
+	provider.RouteDirection
+	URL string `json:"url"`
+}
+
+type stopResource struct {
+	provider.PlaceCode
+	URL string `json:"url"`
+}
+
+func main() {
+	providerName := flag.String("provider", "nextrip", "transit provider backend: nextrip, entur, prim, tfl, or gtfs")
+	gtfsFeed := flag.String("gtfs-feed", "", "GTFS static feed (zip URL or local path); required for --provider gtfs")
+	gtfsRealtimeURL := flag.String("gtfs-realtime-url", "", "GTFS-Realtime TripUpdates feed URL used to live-adjust --provider gtfs departures")
+	flag.Parse()
+
+	p, err := newProvider(context.Background(), *providerName, *gtfsFeed, *gtfsRealtimeURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	addr := ":8080"
+	if v := os.Getenv("BUSSTOP_ADDR"); v != "" {
+		addr = v
+	}
+
+	s := newServer(p)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/routes", s.handleRoutes)
+	mux.HandleFunc("/api/v1/routes/", s.handleRouteDirections)
+	mux.HandleFunc("/api/v1/stops/", s.handleStops)
+	mux.HandleFunc("/api/v1/departures/", s.handleDepartures)
+
+	log.Printf("busstop-server listening on %s using provider %q", addr, *providerName)
+	log.Fatal(http.ListenAndServe(addr, mux))
+}
+
+// newProvider builds the Provider named by providerName. "gtfs" is
+// handled here rather than in provider.New, since it loads a static
+// feed (and optionally layers a GTFS-Realtime TripUpdates feed on top)
+// instead of just reading credentials from the environment.
+func newProvider(ctx context.Context, providerName string, gtfsFeed string, gtfsRealtimeURL string) (provider.Provider, error) {
+	if strings.ToLower(providerName) != "gtfs" {
+		return provider.New(providerName)
+	}
+
+	if gtfsFeed == "" {
+		return nil, fmt.Errorf("--provider gtfs requires --gtfs-feed")
+	}
+	static, err := gtfs.LoadStatic(ctx, gtfsFeed)
+	if err != nil {
+		return nil, fmt.Errorf("loading GTFS feed: %w", err)
+	}
+	return gtfs.Realtime{Static: static, FeedURL: gtfsRealtimeURL}, nil
+}
+
+// handleRoutes serves GET /api/v1/routes.
+func (s *server) handleRoutes(w http.ResponseWriter, r *http.Request) {
+	body, err := cached(s.routes, "routes", func() (interface{}, error) {
+		routes, err := s.provider.Routes(r.Context())
+		if err != nil {
+			return nil, err
+		}
+		resources := make([]routeResource, 0, len(routes))
+		for _, route := range routes {
+			resources = append(resources, routeResource{
+				Route: route,
+				URL:   fmt.Sprintf("/api/v1/routes/%s/directions", route.Route_id),
+			})
+		}
+		return resources, nil
+	})
+	writeJSON(w, r, body, err)
+}
+
+// handleRouteDirections serves GET /api/v1/routes/{route}/directions.
+func (s *server) handleRouteDirections(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/routes/"), "/"), "/")
+	if len(parts) != 2 || parts[1] != "directions" {
+		http.NotFound(w, r)
+		return
+	}
+	routeID := parts[0]
+
+	body, err := cached(s.directions, routeID, func() (interface{}, error) {
+		directions, err := s.provider.Directions(r.Context(), routeID)
+		if err != nil {
+			return nil, err
+		}
+		resources := make([]directionResource, 0, len(directions))
+		for _, direction := range directions {
+			resources = append(resources, directionResource{
+				RouteDirection: direction,
+				URL:            fmt.Sprintf("/api/v1/stops/%s/%d", routeID, direction.Direction_id),
+			})
+		}
+		return resources, nil
+	})
+	writeJSON(w, r, body, err)
+}
+
+// handleStops serves GET /api/v1/stops/{route}/{direction}.
+func (s *server) handleStops(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/stops/"), "/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	routeID := parts[0]
+	directionID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		http.Error(w, "direction must be numeric", http.StatusBadRequest)
+		return
+	}
+
+	body, err := cached(s.stops, fmt.Sprintf("%s/%d", routeID, directionID), func() (interface{}, error) {
+		stops, err := s.provider.Stops(r.Context(), routeID, directionID)
+		if err != nil {
+			return nil, err
+		}
+		resources := make([]stopResource, 0, len(stops))
+		for _, stop := range stops {
+			resources = append(resources, stopResource{
+				PlaceCode: stop,
+				URL:       fmt.Sprintf("/api/v1/departures/%s/%d/%s", routeID, directionID, stop.Place_code),
+			})
+		}
+		return resources, nil
+	})
+	writeJSON(w, r, body, err)
+}
+
+// handleDepartures serves GET /api/v1/departures/{route}/{direction}/{stop}.
+func (s *server) handleDepartures(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/departures/"), "/"), "/")
+	if len(parts) != 3 {
+		http.NotFound(w, r)
+		return
+	}
+	routeID := parts[0]
+	directionID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		http.Error(w, "direction must be numeric", http.StatusBadRequest)
+		return
+	}
+	placeCode := parts[2]
+
+	body, err := cached(s.departures, fmt.Sprintf("%s/%d/%s", routeID, directionID, placeCode), func() (interface{}, error) {
+		return s.provider.Departures(r.Context(), routeID, directionID, placeCode)
+	})
+	writeJSON(w, r, body, err)
+}
+
+// cached returns the JSON-encoded value for key from c, computing and
+// storing it via fetch on a miss.
+func cached(c *ttlCache, key string, fetch func() (interface{}, error)) ([]byte, error) {
+	if body, ok := c.Get(key); ok {
+		return body, nil
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Set(key, body)
+	return body, nil
+}
+
+// writeJSON writes body as the response, honoring If-None-Match against
+// an ETag computed from the body's contents.
+func writeJSON(w http.ResponseWriter, r *http.Request, body []byte, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	sum := sha1.Sum(body)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	w.Header().Set("ETag", etag)
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
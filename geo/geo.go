@@ -0,0 +1,125 @@
+// Package geo finds the nearest stops on a route to a rider's
+// coordinates, using a route's GTFS shapes.txt geometry to pick stops
+// downstream of where the rider would board rather than just the
+// closest stops as the crow flies.
+package geo
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geo"
+
+	"github.com/JoseBeto/BusStop/gtfs"
+	"github.com/JoseBeto/BusStop/provider"
+)
+
+// Index answers nearest-stop queries against a loaded GTFS feed.
+type Index struct {
+	static *gtfs.Static
+}
+
+// NewIndex builds an Index over static's routes, stops, and shapes.
+func NewIndex(static *gtfs.Static) *Index {
+	return &Index{static: static}
+}
+
+// NearestStops finds the direction of route whose shape passes closest
+// to (lat, lon), then returns up to n stops downstream of that point
+// along with the walking distance in meters to reach the route.
+func (idx *Index) NearestStops(lat, lon float64, route string, n int) ([]provider.PlaceCode, float64) {
+	ctx := context.Background()
+	user := orb.Point{lon, lat}
+
+	directions, err := idx.static.Directions(ctx, route)
+	if err != nil {
+		return nil, 0
+	}
+
+	bestDist := math.Inf(1)
+	bestDirectionID := -1
+	bestSegment := -1
+
+	for _, d := range directions {
+		shape := idx.static.RouteShape(route, d.Direction_id)
+		for segment := 0; segment < len(shape)-1; segment++ {
+			_, dist := projectToSegment(user, shape[segment], shape[segment+1])
+			if dist < bestDist {
+				bestDist = dist
+				bestDirectionID = d.Direction_id
+				bestSegment = segment
+			}
+		}
+	}
+
+	if bestDirectionID == -1 {
+		return nil, 0
+	}
+
+	stops, err := idx.static.Stops(ctx, route, bestDirectionID)
+	if err != nil {
+		return nil, 0
+	}
+	shape := idx.static.RouteShape(route, bestDirectionID)
+
+	type stopAtIndex struct {
+		stop  provider.PlaceCode
+		index int
+	}
+	indexed := make([]stopAtIndex, 0, len(stops))
+	for _, stop := range stops {
+		location, ok := idx.static.StopLocation(stop.Place_code)
+		if !ok {
+			continue
+		}
+		indexed = append(indexed, stopAtIndex{stop: stop, index: nearestSegment(location, shape)})
+	}
+	sort.Slice(indexed, func(i, j int) bool { return indexed[i].index < indexed[j].index })
+
+	downstream := make([]provider.PlaceCode, 0, n)
+	for _, s := range indexed {
+		if s.index <= bestSegment {
+			continue
+		}
+		downstream = append(downstream, s.stop)
+		if len(downstream) == n {
+			break
+		}
+	}
+
+	return downstream, bestDist
+}
+
+// nearestSegment returns the index of the shape segment closest to p.
+func nearestSegment(p orb.Point, shape []orb.Point) int {
+	best := math.Inf(1)
+	bestIndex := -1
+	for i := 0; i < len(shape)-1; i++ {
+		_, dist := projectToSegment(p, shape[i], shape[i+1])
+		if dist < best {
+			best = dist
+			bestIndex = i
+		}
+	}
+	return bestIndex
+}
+
+// projectToSegment projects p onto the segment (a, b), clamped to the
+// segment's endpoints, and returns the projected point and the geodesic
+// (haversine) distance from p to it.
+func projectToSegment(p, a, b orb.Point) (orb.Point, float64) {
+	abX, abY := b[0]-a[0], b[1]-a[1]
+	apX, apY := p[0]-a[0], p[1]-a[1]
+
+	lengthSquared := abX*abX + abY*abY
+	t := 0.0
+	if lengthSquared > 0 {
+		t = (apX*abX + apY*abY) / lengthSquared
+		t = math.Max(0, math.Min(1, t))
+	}
+
+	projected := orb.Point{a[0] + t*abX, a[1] + t*abY}
+	return projected, geo.DistanceHaversine(p, projected)
+}
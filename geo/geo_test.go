@@ -0,0 +1,147 @@
+package geo
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paulmach/orb"
+
+	"github.com/JoseBeto/BusStop/gtfs"
+)
+
+// buildFixtureFeed writes a minimal GTFS feed to a zip file under
+// t.TempDir() and returns its path: one route with a single
+// north-south direction, three stops in order along that line.
+func buildFixtureFeed(t *testing.T) string {
+	t.Helper()
+
+	files := map[string]string{
+		"routes.txt": "route_id,route_short_name,route_long_name\n" +
+			"1,1,Route One\n",
+		"trips.txt": "route_id,service_id,trip_id,trip_headsign,direction_id,shape_id\n" +
+			"1,WEEKDAY,T1,Downtown,0,S1\n",
+		"stops.txt": "stop_id,stop_name,stop_lat,stop_lon\n" +
+			"A,Stop A,0.1,0.0\n" +
+			"B,Stop B,1.5,0.0\n" +
+			"C,Stop C,2.5,0.0\n",
+		"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+			"T1,08:00:00,08:00:00,A,1\n" +
+			"T1,08:05:00,08:05:00,B,2\n" +
+			"T1,08:10:00,08:10:00,C,3\n",
+		"calendar.txt": "service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\n" +
+			"WEEKDAY,1,1,1,1,1,1,1,20240101,20261231\n",
+		"shapes.txt": "shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence\n" +
+			"S1,0.0,0.0,1\n" +
+			"S1,1.0,0.0,2\n" +
+			"S1,2.0,0.0,3\n" +
+			"S1,3.0,0.0,4\n",
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zw.Create(%q): %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("write %q: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zw.Close: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "feed.zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestIndexNearestStops(t *testing.T) {
+	path := buildFixtureFeed(t)
+	static, err := gtfs.LoadStatic(context.Background(), path)
+	if err != nil {
+		t.Fatalf("LoadStatic: %v", err)
+	}
+	idx := NewIndex(static)
+
+	// A rider standing just off the line between stops A and B should
+	// be matched downstream to B then C.
+	stops, dist := idx.NearestStops(0.5, 0.1, "1", 2)
+	if len(stops) != 2 || stops[0].Place_code != "B" || stops[1].Place_code != "C" {
+		t.Fatalf("NearestStops = %+v, want [B C]", stops)
+	}
+	if dist <= 0 {
+		t.Errorf("NearestStops dist = %v, want > 0", dist)
+	}
+
+	// A rider level with the last stop, on the final shape segment, has
+	// no downstream stops left.
+	stops, _ = idx.NearestStops(2.5, 0.1, "1", 2)
+	if len(stops) != 0 {
+		t.Fatalf("NearestStops past the end = %+v, want none", stops)
+	}
+
+	// An unknown route has no shape to match against.
+	stops, dist = idx.NearestStops(0, 0, "nonexistent", 1)
+	if stops != nil || dist != 0 {
+		t.Fatalf("NearestStops(nonexistent) = %+v, %v, want nil, 0", stops, dist)
+	}
+}
+
+func TestProjectToSegment(t *testing.T) {
+	a := orb.Point{0, 0}
+	b := orb.Point{0, 1} // due north of a
+
+	tests := []struct {
+		name      string
+		p         orb.Point
+		wantPoint orb.Point
+	}{
+		{name: "midpoint projects onto segment", p: orb.Point{1, 0.5}, wantPoint: orb.Point{0, 0.5}},
+		{name: "before start clamps to a", p: orb.Point{1, -1}, wantPoint: a},
+		{name: "past end clamps to b", p: orb.Point{1, 2}, wantPoint: b},
+		{name: "on the line", p: orb.Point{0, 0.25}, wantPoint: orb.Point{0, 0.25}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, dist := projectToSegment(tt.p, a, b)
+			if math.Abs(got[0]-tt.wantPoint[0]) > 1e-9 || math.Abs(got[1]-tt.wantPoint[1]) > 1e-9 {
+				t.Errorf("projectToSegment(%v) = %v, want %v", tt.p, got, tt.wantPoint)
+			}
+			if dist < 0 {
+				t.Errorf("projectToSegment(%v) dist = %v, want >= 0", tt.p, dist)
+			}
+		})
+	}
+}
+
+func TestNearestSegment(t *testing.T) {
+	shape := []orb.Point{{0, 0}, {1, 0}, {2, 0}, {3, 0}}
+
+	tests := []struct {
+		name string
+		p    orb.Point
+		want int
+	}{
+		{name: "closest to first segment", p: orb.Point{0.1, 0.5}, want: 0},
+		{name: "closest to last segment", p: orb.Point{2.9, 0.5}, want: 2},
+		{name: "closest to middle segment", p: orb.Point{1.5, 0.5}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nearestSegment(tt.p, shape); got != tt.want {
+				t.Errorf("nearestSegment(%v) = %d, want %d", tt.p, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,579 @@
+// Package gtfs loads a static GTFS feed (routes.txt, trips.txt,
+// stops.txt, stop_times.txt, calendar.txt, and optionally
+// calendar_dates.txt) from a zip file - fetched over HTTP or read from
+// disk - into in-memory indexes, and answers the same
+// Routes/Directions/Stops/Departures queries as provider.Provider
+// without needing a live upstream API. This makes BusStop usable for any
+// agency that publishes GTFS, not just Metro Transit.
+package gtfs
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paulmach/orb"
+
+	"github.com/JoseBeto/BusStop/provider"
+)
+
+// direction pairs a GTFS direction_id with the headsign BusStop shows
+// for it.
+type direction struct {
+	id       int
+	headsign string
+}
+
+// stopOnRoute is a stop as it appears along one route+direction, in
+// schedule order.
+type stopOnRoute struct {
+	stopID   string
+	sequence int
+}
+
+// tripStopTime is one trip's scheduled visit to a stop, used both to
+// build the stop order for a route+direction and to answer Departures.
+type tripStopTime struct {
+	tripID    string
+	stopID    string
+	sequence  int
+	departure time.Duration // time-of-day offset, GTFS allows >24h
+	serviceID string
+}
+
+// service is a calendar.txt row: the weekdays a service_id runs on,
+// bounded by its start_date/end_date window.
+type service struct {
+	days      map[time.Weekday]bool
+	startDate time.Time
+	endDate   time.Time
+}
+
+// Static is a GTFS static feed loaded fully into memory.
+type Static struct {
+	routes         map[string]provider.Route
+	directions     map[string][]direction    // route_id -> directions
+	stopOrder      map[string][]stopOnRoute  // "route_id/direction_id" -> ordered stops
+	stopTimes      map[string][]tripStopTime // "route_id/direction_id/stop_id" -> visits
+	stopNames      map[string]string         // stop_id -> stop_name
+	stopLocations  map[string]orb.Point      // stop_id -> (lon, lat)
+	directionShape map[string]string         // "route_id/direction_id" -> shape_id
+	shapes         map[string][]orb.Point    // shape_id -> ordered points
+	services       map[string]service        // service_id -> calendar.txt row
+	// calendarDates holds calendar_dates.txt exceptions: service_id ->
+	// "YYYYMMDD" -> exception_type (1 = added, 2 = removed), overriding
+	// services for that one date.
+	calendarDates map[string]map[string]int
+}
+
+// LoadStatic reads a GTFS zip from source, which may be an http(s) URL
+// or a local file path, and builds the indexes used to answer Routes,
+// Directions, Stops, and Departures.
+func LoadStatic(ctx context.Context, source string) (*Static, error) {
+	zr, err := openFeed(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := readRoutes(zr)
+	if err != nil {
+		return nil, err
+	}
+	trips, err := readTrips(zr)
+	if err != nil {
+		return nil, err
+	}
+	stopNames, stopLocations, err := readStops(zr)
+	if err != nil {
+		return nil, err
+	}
+	stopTimesByTrip, err := readStopTimes(zr)
+	if err != nil {
+		return nil, err
+	}
+	services, err := readCalendar(zr)
+	if err != nil {
+		return nil, err
+	}
+	calendarDates, err := readCalendarDates(zr)
+	if err != nil {
+		return nil, err
+	}
+	shapes, err := readShapes(zr)
+	if err != nil {
+		// shapes.txt is optional in GTFS; geo's Index.NearestStops simply won't
+		// work for feeds that omit it.
+		shapes = map[string][]orb.Point{}
+	}
+
+	s := &Static{
+		routes:         routes,
+		directions:     map[string][]direction{},
+		stopOrder:      map[string][]stopOnRoute{},
+		stopTimes:      map[string][]tripStopTime{},
+		stopNames:      stopNames,
+		stopLocations:  stopLocations,
+		directionShape: map[string]string{},
+		shapes:         shapes,
+		services:       services,
+		calendarDates:  calendarDates,
+	}
+	s.index(trips, stopTimesByTrip)
+	return s, nil
+}
+
+// gtfsTrip is a parsed row of trips.txt.
+type gtfsTrip struct {
+	routeID     string
+	serviceID   string
+	tripID      string
+	headsign    string
+	directionID int
+	shapeID     string
+}
+
+// index builds directions, stopOrder, and stopTimes from trips and their
+// stop_times, keeping the first headsign seen per route+direction and
+// the first stop_sequence seen per stop on that route+direction.
+func (s *Static) index(trips []gtfsTrip, stopTimesByTrip map[string][]tripStopTime) {
+	seenDirection := map[string]bool{}
+	seenStop := map[string]bool{}
+
+	for _, trip := range trips {
+		dirKey := trip.routeID + "/" + strconv.Itoa(trip.directionID)
+		if !seenDirection[dirKey] {
+			seenDirection[dirKey] = true
+			s.directions[trip.routeID] = append(s.directions[trip.routeID], direction{
+				id:       trip.directionID,
+				headsign: trip.headsign,
+			})
+			s.directionShape[dirKey] = trip.shapeID
+		}
+
+		for _, st := range stopTimesByTrip[trip.tripID] {
+			st.serviceID = trip.serviceID
+			stopKey := dirKey + "/" + st.stopID
+			s.stopTimes[stopKey] = append(s.stopTimes[stopKey], st)
+
+			orderKey := dirKey + "#" + st.stopID
+			if !seenStop[orderKey] {
+				seenStop[orderKey] = true
+				s.stopOrder[dirKey] = append(s.stopOrder[dirKey], stopOnRoute{stopID: st.stopID, sequence: st.sequence})
+			}
+		}
+	}
+
+	for key, stops := range s.stopOrder {
+		sort.Slice(stops, func(i, j int) bool { return stops[i].sequence < stops[j].sequence })
+		s.stopOrder[key] = stops
+	}
+}
+
+func (s *Static) Routes(ctx context.Context) ([]provider.Route, error) {
+	routes := make([]provider.Route, 0, len(s.routes))
+	for _, route := range s.routes {
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Route_id < routes[j].Route_id })
+	return routes, nil
+}
+
+func (s *Static) Directions(ctx context.Context, routeID string) ([]provider.RouteDirection, error) {
+	dirs := s.directions[routeID]
+	out := make([]provider.RouteDirection, 0, len(dirs))
+	for _, d := range dirs {
+		out = append(out, provider.RouteDirection{Direction_id: d.id, Direction_name: d.headsign})
+	}
+	return out, nil
+}
+
+func (s *Static) Stops(ctx context.Context, routeID string, directionID int) ([]provider.PlaceCode, error) {
+	key := routeID + "/" + strconv.Itoa(directionID)
+	stops := s.stopOrder[key]
+	out := make([]provider.PlaceCode, 0, len(stops))
+	for _, stop := range stops {
+		out = append(out, provider.PlaceCode{Place_code: stop.stopID, Description: s.stopNames[stop.stopID]})
+	}
+	return out, nil
+}
+
+// RouteShape returns the GTFS shapes.txt LineString for routeID's
+// directionID, as an ordered list of points, or nil if the feed has no
+// shape for it.
+func (s *Static) RouteShape(routeID string, directionID int) []orb.Point {
+	shapeID := s.directionShape[routeID+"/"+strconv.Itoa(directionID)]
+	return s.shapes[shapeID]
+}
+
+// StopLocation returns stopID's coordinates, and whether stopID was
+// found in stops.txt.
+func (s *Static) StopLocation(stopID string) (orb.Point, bool) {
+	p, ok := s.stopLocations[stopID]
+	return p, ok
+}
+
+// Departures returns today's remaining scheduled departures for
+// routeID/directionID at stopCode, in schedule order. busstop.GetDepartures
+// treats the first entry as the next bus.
+func (s *Static) Departures(ctx context.Context, routeID string, directionID int, stopCode string) (provider.RouteDepartures, error) {
+	trips := s.tripDepartures(routeID, directionID, stopCode)
+	departures := make([]provider.Departure, len(trips))
+	for i, t := range trips {
+		departures[i] = t.Departure
+	}
+	return provider.RouteDepartures{Departures: departures}, nil
+}
+
+// tripDeparture is a scheduled departure together with the trip that
+// makes it, so Realtime can match it against a TripUpdates feed.
+type tripDeparture struct {
+	TripID    string
+	Departure provider.Departure
+}
+
+// tripDepartures returns today's remaining scheduled departures for
+// routeID/directionID at stopCode, sorted earliest first, with each
+// departure's trip_id attached.
+func (s *Static) tripDepartures(routeID string, directionID int, stopCode string) []tripDeparture {
+	key := routeID + "/" + strconv.Itoa(directionID) + "/" + stopCode
+	now := time.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var trips []tripDeparture
+	for _, st := range s.stopTimes[key] {
+		if !s.serviceRunsOn(st.serviceID, now) {
+			continue
+		}
+		departureTime := midnight.Add(st.departure)
+		if departureTime.Before(now) {
+			continue
+		}
+		trips = append(trips, tripDeparture{
+			TripID: st.tripID,
+			Departure: provider.Departure{
+				Departure_time: departureTime.Unix(),
+				Scheduled_time: departureTime.Unix(),
+			},
+		})
+	}
+
+	sort.Slice(trips, func(i, j int) bool { return trips[i].Departure.Departure_time < trips[j].Departure.Departure_time })
+	return trips
+}
+
+// serviceRunsOn reports whether serviceID operates on day, consulting
+// calendar_dates.txt first: an exception_type of 1 (added) or 2
+// (removed) for that exact date overrides calendar.txt outright.
+// Otherwise it falls back to calendar.txt's weekday flags, bounded by
+// the service's start_date/end_date window.
+func (s *Static) serviceRunsOn(serviceID string, day time.Time) bool {
+	date := day.Format("20060102")
+	if exceptionType, ok := s.calendarDates[serviceID][date]; ok {
+		return exceptionType == 1
+	}
+
+	svc, ok := s.services[serviceID]
+	if !ok {
+		return false
+	}
+	if day.Before(svc.startDate) || day.After(svc.endDate) {
+		return false
+	}
+	return svc.days[day.Weekday()]
+}
+
+func openFeed(ctx context.Context, source string) (*zip.Reader, error) {
+	var data []byte
+
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		data, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		data, err = ioutil.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return zip.NewReader(bytes.NewReader(data), int64(len(data)))
+}
+
+func openCSV(zr *zip.Reader, name string) (*csv.Reader, func(), error) {
+	for _, f := range zr.File {
+		if f.Name == name {
+			rc, err := f.Open()
+			if err != nil {
+				return nil, nil, err
+			}
+			return csv.NewReader(rc), func() { rc.Close() }, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("gtfs: %s not found in feed", name)
+}
+
+// readCSVRows reads header + data rows from name, returning a column
+// name -> index map and the remaining rows.
+func readCSVRows(zr *zip.Reader, name string) (map[string]int, [][]string, error) {
+	r, closeFile, err := openCSV(zr, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeFile()
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.TrimSpace(h)] = i
+	}
+
+	var rows [][]string
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, row)
+	}
+	return cols, rows, nil
+}
+
+func readRoutes(zr *zip.Reader) (map[string]provider.Route, error) {
+	cols, rows, err := readCSVRows(zr, "routes.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make(map[string]provider.Route, len(rows))
+	for _, row := range rows {
+		label := row[cols["route_short_name"]]
+		if label == "" {
+			label = row[cols["route_long_name"]]
+		}
+		routes[row[cols["route_id"]]] = provider.Route{
+			Route_id:    row[cols["route_id"]],
+			Route_label: label,
+		}
+	}
+	return routes, nil
+}
+
+func readTrips(zr *zip.Reader) ([]gtfsTrip, error) {
+	cols, rows, err := readCSVRows(zr, "trips.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	trips := make([]gtfsTrip, 0, len(rows))
+	for _, row := range rows {
+		directionID, _ := strconv.Atoi(row[cols["direction_id"]])
+		var shapeID string
+		if i, ok := cols["shape_id"]; ok {
+			shapeID = row[i]
+		}
+		trips = append(trips, gtfsTrip{
+			routeID:     row[cols["route_id"]],
+			serviceID:   row[cols["service_id"]],
+			tripID:      row[cols["trip_id"]],
+			headsign:    row[cols["trip_headsign"]],
+			directionID: directionID,
+			shapeID:     shapeID,
+		})
+	}
+	return trips, nil
+}
+
+func readStops(zr *zip.Reader) (names map[string]string, locations map[string]orb.Point, err error) {
+	cols, rows, err := readCSVRows(zr, "stops.txt")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names = make(map[string]string, len(rows))
+	locations = make(map[string]orb.Point, len(rows))
+	for _, row := range rows {
+		stopID := row[cols["stop_id"]]
+		names[stopID] = row[cols["stop_name"]]
+
+		lat, errLat := strconv.ParseFloat(row[cols["stop_lat"]], 64)
+		lon, errLon := strconv.ParseFloat(row[cols["stop_lon"]], 64)
+		if errLat == nil && errLon == nil {
+			locations[stopID] = orb.Point{lon, lat}
+		}
+	}
+	return names, locations, nil
+}
+
+func readShapes(zr *zip.Reader) (map[string][]orb.Point, error) {
+	cols, rows, err := readCSVRows(zr, "shapes.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	type shapePoint struct {
+		point    orb.Point
+		sequence int
+	}
+	byShape := map[string][]shapePoint{}
+	for _, row := range rows {
+		lat, errLat := strconv.ParseFloat(row[cols["shape_pt_lat"]], 64)
+		lon, errLon := strconv.ParseFloat(row[cols["shape_pt_lon"]], 64)
+		if errLat != nil || errLon != nil {
+			continue
+		}
+		sequence, _ := strconv.Atoi(row[cols["shape_pt_sequence"]])
+		shapeID := row[cols["shape_id"]]
+		byShape[shapeID] = append(byShape[shapeID], shapePoint{point: orb.Point{lon, lat}, sequence: sequence})
+	}
+
+	shapes := make(map[string][]orb.Point, len(byShape))
+	for shapeID, points := range byShape {
+		sort.Slice(points, func(i, j int) bool { return points[i].sequence < points[j].sequence })
+		line := make([]orb.Point, len(points))
+		for i, p := range points {
+			line[i] = p.point
+		}
+		shapes[shapeID] = line
+	}
+	return shapes, nil
+}
+
+func readStopTimes(zr *zip.Reader) (map[string][]tripStopTime, error) {
+	cols, rows, err := readCSVRows(zr, "stop_times.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	byTrip := map[string][]tripStopTime{}
+	for _, row := range rows {
+		sequence, _ := strconv.Atoi(row[cols["stop_sequence"]])
+		departure, err := parseGTFSTime(row[cols["departure_time"]])
+		if err != nil {
+			continue
+		}
+		tripID := row[cols["trip_id"]]
+		byTrip[tripID] = append(byTrip[tripID], tripStopTime{
+			tripID:    tripID,
+			stopID:    row[cols["stop_id"]],
+			sequence:  sequence,
+			departure: departure,
+		})
+	}
+	return byTrip, nil
+}
+
+func readCalendar(zr *zip.Reader) (map[string]service, error) {
+	cols, rows, err := readCSVRows(zr, "calendar.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	days := []struct {
+		column string
+		day    time.Weekday
+	}{
+		{"sunday", time.Sunday}, {"monday", time.Monday}, {"tuesday", time.Tuesday},
+		{"wednesday", time.Wednesday}, {"thursday", time.Thursday},
+		{"friday", time.Friday}, {"saturday", time.Saturday},
+	}
+
+	services := make(map[string]service, len(rows))
+	for _, row := range rows {
+		active := map[time.Weekday]bool{}
+		for _, d := range days {
+			active[d.day] = row[cols[d.column]] == "1"
+		}
+		startDate, err := time.Parse("20060102", row[cols["start_date"]])
+		if err != nil {
+			return nil, fmt.Errorf("gtfs: calendar.txt: %w", err)
+		}
+		endDate, err := time.Parse("20060102", row[cols["end_date"]])
+		if err != nil {
+			return nil, fmt.Errorf("gtfs: calendar.txt: %w", err)
+		}
+		services[row[cols["service_id"]]] = service{days: active, startDate: startDate, endDate: endDate}
+	}
+	return services, nil
+}
+
+// readCalendarDates reads calendar_dates.txt's per-date exceptions to
+// calendar.txt. The file is optional in GTFS, so a missing file isn't an
+// error.
+func readCalendarDates(zr *zip.Reader) (map[string]map[string]int, error) {
+	cols, rows, err := readCSVRows(zr, "calendar_dates.txt")
+	if err != nil {
+		if strings.Contains(err.Error(), "not found in feed") {
+			return map[string]map[string]int{}, nil
+		}
+		return nil, err
+	}
+
+	exceptions := map[string]map[string]int{}
+	for _, row := range rows {
+		serviceID := row[cols["service_id"]]
+		exceptionType, err := strconv.Atoi(row[cols["exception_type"]])
+		if err != nil {
+			return nil, fmt.Errorf("gtfs: calendar_dates.txt: %w", err)
+		}
+		if exceptions[serviceID] == nil {
+			exceptions[serviceID] = map[string]int{}
+		}
+		exceptions[serviceID][row[cols["date"]]] = exceptionType
+	}
+	return exceptions, nil
+}
+
+// parseGTFSTime parses a GTFS HH:MM:SS time-of-day, where hours may
+// exceed 23 for trips that run past midnight.
+func parseGTFSTime(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("gtfs: malformed time %q", s)
+	}
+	h, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, err
+	}
+	m, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, err
+	}
+	sec, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second, nil
+}
@@ -0,0 +1,192 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// buildFixtureFeed writes files as a GTFS feed zip under t.TempDir()
+// and returns its path.
+func buildFixtureFeed(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zw.Create(%q): %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("write %q: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zw.Close: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "feed.zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	return path
+}
+
+// fixtureFiles returns a minimal GTFS feed: one route, one direction,
+// three stops, one trip.
+func fixtureFiles() map[string]string {
+	return map[string]string{
+		"routes.txt": "route_id,route_short_name,route_long_name\n" +
+			"1,1,Route One\n",
+		"trips.txt": "route_id,service_id,trip_id,trip_headsign,direction_id,shape_id\n" +
+			"1,WEEKDAY,T1,Downtown,0,S1\n",
+		"stops.txt": "stop_id,stop_name,stop_lat,stop_lon\n" +
+			"S1,First St,44.970,-93.260\n" +
+			"S2,Second St,44.980,-93.250\n" +
+			"S3,Third St,44.990,-93.240\n",
+		"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+			"T1,08:00:00,08:00:00,S1,1\n" +
+			"T1,08:05:00,08:05:00,S2,2\n" +
+			"T1,08:10:00,08:10:00,S3,3\n",
+		"calendar.txt": "service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\n" +
+			"WEEKDAY,1,1,1,1,1,0,0,20240101,20261231\n",
+		"shapes.txt": "shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence\n" +
+			"S1,44.970,-93.260,1\n" +
+			"S1,44.980,-93.250,2\n" +
+			"S1,44.990,-93.240,3\n",
+	}
+}
+
+func TestLoadStatic(t *testing.T) {
+	path := buildFixtureFeed(t, fixtureFiles())
+
+	static, err := LoadStatic(context.Background(), path)
+	if err != nil {
+		t.Fatalf("LoadStatic: %v", err)
+	}
+
+	routes, err := static.Routes(context.Background())
+	if err != nil {
+		t.Fatalf("Routes: %v", err)
+	}
+	if len(routes) != 1 || routes[0].Route_id != "1" || routes[0].Route_label != "1" {
+		t.Fatalf("Routes = %+v, want one route %q", routes, "1")
+	}
+
+	directions, err := static.Directions(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Directions: %v", err)
+	}
+	if len(directions) != 1 || directions[0].Direction_id != 0 || directions[0].Direction_name != "Downtown" {
+		t.Fatalf("Directions = %+v, want direction 0 \"Downtown\"", directions)
+	}
+
+	stops, err := static.Stops(context.Background(), "1", 0)
+	if err != nil {
+		t.Fatalf("Stops: %v", err)
+	}
+	wantStops := []string{"S1", "S2", "S3"}
+	if len(stops) != len(wantStops) {
+		t.Fatalf("Stops = %+v, want %d stops in sequence order", stops, len(wantStops))
+	}
+	for i, want := range wantStops {
+		if stops[i].Place_code != want {
+			t.Errorf("Stops[%d].Place_code = %q, want %q", i, stops[i].Place_code, want)
+		}
+	}
+
+	shape := static.RouteShape("1", 0)
+	if len(shape) != 3 {
+		t.Fatalf("RouteShape = %v, want 3 points", shape)
+	}
+
+	if _, ok := static.StopLocation("S2"); !ok {
+		t.Errorf("StopLocation(%q) not found", "S2")
+	}
+	if _, ok := static.StopLocation("nonexistent"); ok {
+		t.Errorf("StopLocation(%q) found, want not found", "nonexistent")
+	}
+}
+
+func TestParseGTFSTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "morning", input: "08:05:00", want: 8*time.Hour + 5*time.Minute},
+		{name: "midnight", input: "00:00:00", want: 0},
+		{name: "past midnight rollover", input: "25:10:30", want: 25*time.Hour + 10*time.Minute + 30*time.Second},
+		{name: "malformed", input: "8:05", wantErr: true},
+		{name: "non-numeric", input: "aa:bb:cc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGTFSTime(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseGTFSTime(%q) = %v, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseGTFSTime(%q) returned error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseGTFSTime(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceRunsOn(t *testing.T) {
+	static := &Static{
+		services: map[string]service{
+			"WEEKDAY": {
+				days:      map[time.Weekday]bool{time.Monday: true, time.Tuesday: true},
+				startDate: date(2024, 1, 1),
+				endDate:   date(2024, 12, 31),
+			},
+		},
+		calendarDates: map[string]map[string]int{
+			"WEEKDAY": {
+				"20240102": 2, // removed: a Tuesday the service doesn't run
+				"20240106": 1, // added: a Saturday the service does run
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		serviceID string
+		day       time.Time
+		want      bool
+	}{
+		{name: "active weekday in range", serviceID: "WEEKDAY", day: date(2024, 1, 1), want: true}, // Monday
+		{name: "inactive weekday", serviceID: "WEEKDAY", day: date(2024, 1, 3), want: false},       // Wednesday
+		{name: "before start_date", serviceID: "WEEKDAY", day: date(2023, 12, 31), want: false},
+		{name: "after end_date", serviceID: "WEEKDAY", day: date(2025, 1, 1), want: false},
+		{name: "calendar_dates removed exception", serviceID: "WEEKDAY", day: date(2024, 1, 2), want: false}, // Tuesday, but removed
+		{name: "calendar_dates added exception", serviceID: "WEEKDAY", day: date(2024, 1, 6), want: true},    // Saturday, but added
+		{name: "unknown service", serviceID: "NOPE", day: date(2024, 1, 1), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := static.serviceRunsOn(tt.serviceID, tt.day); got != tt.want {
+				t.Errorf("serviceRunsOn(%q, %s) = %v, want %v", tt.serviceID, tt.day.Format("2006-01-02"), got, tt.want)
+			}
+		})
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
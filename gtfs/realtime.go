@@ -0,0 +1,111 @@
+package gtfs
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+
+	gtfsrt "github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/JoseBeto/BusStop/provider"
+)
+
+// Realtime layers a GTFS-Realtime TripUpdates feed on top of a Static
+// schedule: Departures returns the realtime-adjusted time for a trip
+// when the feed has an update for it, and falls back to the scheduled
+// stop_times entry otherwise.
+type Realtime struct {
+	Static *Static
+	// FeedURL is the TripUpdates protobuf feed to poll. If empty,
+	// Realtime behaves exactly like Static.
+	FeedURL string
+	Client  *http.Client
+}
+
+func (r Realtime) client() *http.Client {
+	if r.Client != nil {
+		return r.Client
+	}
+	return http.DefaultClient
+}
+
+func (r Realtime) Routes(ctx context.Context) ([]provider.Route, error) {
+	return r.Static.Routes(ctx)
+}
+
+func (r Realtime) Directions(ctx context.Context, routeID string) ([]provider.RouteDirection, error) {
+	return r.Static.Directions(ctx, routeID)
+}
+
+func (r Realtime) Stops(ctx context.Context, routeID string, directionID int) ([]provider.PlaceCode, error) {
+	return r.Static.Stops(ctx, routeID, directionID)
+}
+
+func (r Realtime) Departures(ctx context.Context, routeID string, directionID int, stopCode string) (provider.RouteDepartures, error) {
+	trips := r.Static.tripDepartures(routeID, directionID, stopCode)
+
+	if r.FeedURL != "" {
+		if updates, err := r.fetchStopTimeUpdates(ctx, stopCode); err == nil {
+			for i, t := range trips {
+				if liveTime, ok := updates[t.TripID]; ok {
+					trips[i].Departure.Departure_time = liveTime
+					trips[i].Departure.Realtime = true
+				}
+			}
+		}
+		// A bad or unreachable realtime feed shouldn't take down
+		// departures entirely; fall back to the static schedule.
+	}
+
+	departures := make([]provider.Departure, len(trips))
+	for i, t := range trips {
+		departures[i] = t.Departure
+	}
+	return provider.RouteDepartures{Departures: departures}, nil
+}
+
+// fetchStopTimeUpdates fetches and decodes the configured TripUpdates
+// feed, returning a map from trip_id to its realtime-adjusted departure
+// time at stopCode.
+func (r Realtime) fetchStopTimeUpdates(ctx context.Context, stopCode string) (map[string]int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.FeedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var feed gtfsrt.FeedMessage
+	if err := proto.Unmarshal(body, &feed); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]int64{}
+	for _, entity := range feed.Entity {
+		tripUpdate := entity.GetTripUpdate()
+		if tripUpdate == nil {
+			continue
+		}
+		for _, stu := range tripUpdate.StopTimeUpdate {
+			if stu.GetStopId() != stopCode {
+				continue
+			}
+			departure := stu.GetDeparture()
+			if departure == nil {
+				continue
+			}
+			updates[tripUpdate.GetTrip().GetTripId()] = departure.GetTime()
+		}
+	}
+	return updates, nil
+}
@@ -0,0 +1,191 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Entur is the Provider for Norway's national journey planner
+// (api.entur.io). Entur models transit as lines, quays, and
+// service journeys rather than routes/directions/stops, so each method
+// below translates via a small GraphQL query.
+type Entur struct {
+	// Client is used for all upstream requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+	// ClientName identifies this application to Entur, as required by
+	// their API terms of use (e.g. "my-app-my-team").
+	ClientName string
+}
+
+const enturEndpoint = "https://api.entur.io/journey-planner/v3/graphql"
+
+func (e Entur) client() *http.Client {
+	if e.Client != nil {
+		return e.Client
+	}
+	return http.DefaultClient
+}
+
+func (e Entur) clientName() string {
+	if e.ClientName != "" {
+		return e.ClientName
+	}
+	return "busstop-busstop"
+}
+
+func (e Entur) query(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	payload, err := json.Marshal(struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}{query, variables})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, enturEndpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("ET-Client-Name", e.clientName())
+
+	resp, err := e.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return err
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+func (e Entur) Routes(ctx context.Context) ([]Route, error) {
+	var result struct {
+		Lines []struct {
+			Id         string `json:"id"`
+			PublicCode string `json:"publicCode"`
+			Name       string `json:"name"`
+		} `json:"lines"`
+	}
+
+	const q = `query { lines { id publicCode name } }`
+	if err := e.query(ctx, q, nil, &result); err != nil {
+		return nil, err
+	}
+
+	routes := make([]Route, 0, len(result.Lines))
+	for _, line := range result.Lines {
+		routes = append(routes, Route{
+			Route_id:    line.Id,
+			Route_label: fmt.Sprintf("%s %s", line.PublicCode, line.Name),
+		})
+	}
+	return routes, nil
+}
+
+func (e Entur) Directions(ctx context.Context, routeID string) ([]RouteDirection, error) {
+	var result struct {
+		Line struct {
+			JourneyPatterns []struct {
+				DirectionType      string `json:"directionType"`
+				DestinationDisplay struct {
+					FrontText string `json:"frontText"`
+				} `json:"destinationDisplay"`
+			} `json:"journeyPatterns"`
+		} `json:"line"`
+	}
+
+	const q = `query($id: ID!) { line(id: $id) { journeyPatterns { directionType destinationDisplay { frontText } } } }`
+	if err := e.query(ctx, q, map[string]interface{}{"id": routeID}, &result); err != nil {
+		return nil, err
+	}
+
+	// Entur doesn't assign directions a stable numeric ID, so BusStop
+	// synthesizes one from the journey pattern's position.
+	directions := make([]RouteDirection, 0, len(result.Line.JourneyPatterns))
+	for i, jp := range result.Line.JourneyPatterns {
+		directions = append(directions, RouteDirection{
+			Direction_id:   i,
+			Direction_name: jp.DestinationDisplay.FrontText,
+		})
+	}
+	return directions, nil
+}
+
+func (e Entur) Stops(ctx context.Context, routeID string, directionID int) ([]PlaceCode, error) {
+	var result struct {
+		Line struct {
+			JourneyPatterns []struct {
+				Quays []struct {
+					Id         string `json:"id"`
+					Name       string `json:"name"`
+					PublicCode string `json:"publicCode"`
+				} `json:"quays"`
+			} `json:"journeyPatterns"`
+		} `json:"line"`
+	}
+
+	const q = `query($id: ID!) { line(id: $id) { journeyPatterns { quays { id name publicCode } } } }`
+	if err := e.query(ctx, q, map[string]interface{}{"id": routeID}, &result); err != nil {
+		return nil, err
+	}
+	if directionID < 0 || directionID >= len(result.Line.JourneyPatterns) {
+		return nil, fmt.Errorf("entur: direction %d not found for line %s", directionID, routeID)
+	}
+
+	quays := result.Line.JourneyPatterns[directionID].Quays
+	stops := make([]PlaceCode, 0, len(quays))
+	for _, quay := range quays {
+		stops = append(stops, PlaceCode{
+			Place_code:  quay.Id,
+			Description: quay.Name,
+		})
+	}
+	return stops, nil
+}
+
+func (e Entur) Departures(ctx context.Context, routeID string, directionID int, stopCode string) (RouteDepartures, error) {
+	var result struct {
+		Quay struct {
+			EstimatedCalls []struct {
+				AimedDepartureTime    string `json:"aimedDepartureTime"`
+				ExpectedDepartureTime string `json:"expectedDepartureTime"`
+				Realtime              bool   `json:"realtime"`
+			} `json:"estimatedCalls"`
+		} `json:"quay"`
+	}
+
+	const q = `query($id: String!) { quay(id: $id) { estimatedCalls(numberOfDepartures: 10) { aimedDepartureTime expectedDepartureTime realtime } } }`
+	if err := e.query(ctx, q, map[string]interface{}{"id": stopCode}, &result); err != nil {
+		return RouteDepartures{}, err
+	}
+
+	departures := make([]Departure, 0, len(result.Quay.EstimatedCalls))
+	for _, call := range result.Quay.EstimatedCalls {
+		expected, err := time.Parse(time.RFC3339, call.ExpectedDepartureTime)
+		if err != nil {
+			continue
+		}
+		departure := Departure{Departure_time: expected.Unix(), Realtime: call.Realtime}
+		if aimed, err := time.Parse(time.RFC3339, call.AimedDepartureTime); err == nil {
+			departure.Scheduled_time = aimed.Unix()
+		}
+		departures = append(departures, departure)
+	}
+	return RouteDepartures{Departures: departures}, nil
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// NexTrip is the Provider backing Metro Transit's NexTrip v2 API
+// (svc.metrotransit.org). This is BusStop's original, and default,
+// backend.
+type NexTrip struct {
+	// Client is used for all upstream requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+func (n NexTrip) client() *http.Client {
+	if n.Client != nil {
+		return n.Client
+	}
+	return http.DefaultClient
+}
+
+func (n NexTrip) get(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+func (n NexTrip) Routes(ctx context.Context) (routes []Route, err error) {
+	err = n.get(ctx, "https://svc.metrotransit.org/nextripv2/routes", &routes)
+	return
+}
+
+func (n NexTrip) Directions(ctx context.Context, routeID string) (directions []RouteDirection, err error) {
+	url := fmt.Sprintf("https://svc.metrotransit.org/nextripv2/directions/%s", routeID)
+	err = n.get(ctx, url, &directions)
+	return
+}
+
+func (n NexTrip) Stops(ctx context.Context, routeID string, directionID int) (stops []PlaceCode, err error) {
+	url := fmt.Sprintf("https://svc.metrotransit.org/nextripv2/stops/%s/%d", routeID, directionID)
+	err = n.get(ctx, url, &stops)
+	return
+}
+
+func (n NexTrip) Departures(ctx context.Context, routeID string, directionID int, stopCode string) (departures RouteDepartures, err error) {
+	url := fmt.Sprintf("https://svc.metrotransit.org/nextripv2/%s/%d/%s", routeID, directionID, stopCode)
+	err = n.get(ctx, url, &departures)
+	return
+}
@@ -0,0 +1,52 @@
+// Package provider defines the common transit-data shape BusStop works
+// with, and the Provider interface that backends (NexTrip, Entur, PRIM,
+// TfL, ...) implement to supply it. Callers pick a Provider once (by
+// flag or config) and the rest of BusStop is agency-agnostic.
+package provider
+
+import "context"
+
+type Route struct {
+	Route_id    string `json:"route_id"`
+	Agency_id   int    `json:"agency_id"`
+	Route_label string `json:"route_label"`
+}
+
+type RouteDirection struct {
+	Direction_id   int    `json:"direction_id"`
+	Direction_name string `json:"direction_name"`
+}
+
+type PlaceCode struct {
+	Place_code  string `json:"place_code"`
+	Description string `json:"description"`
+}
+
+type RouteDepartures struct {
+	Departures []Departure `json:"departures"`
+}
+
+type Departure struct {
+	// Departure_time is the best-known departure: realtime if the
+	// backend has it, scheduled otherwise.
+	Departure_time int64 `json:"departure_time"`
+	// Scheduled_time is the static schedule's departure time, even when
+	// Departure_time has been adjusted by realtime data. Zero when the
+	// backend doesn't expose a schedule distinct from Departure_time.
+	Scheduled_time int64 `json:"scheduled_time"`
+	// Realtime is true when Departure_time reflects live vehicle data
+	// rather than the static schedule. NexTrip calls this "actual".
+	Realtime bool `json:"actual"`
+}
+
+// Provider fetches routes, directions, stops, and departures from a
+// single transit agency's native API and translates them into BusStop's
+// common types. Every method takes a context so slow upstreams (Entur's
+// GraphQL endpoint in particular) can be cancelled or timed out by the
+// caller.
+type Provider interface {
+	Routes(ctx context.Context) ([]Route, error)
+	Directions(ctx context.Context, routeID string) ([]RouteDirection, error)
+	Stops(ctx context.Context, routeID string, directionID int) ([]PlaceCode, error)
+	Departures(ctx context.Context, routeID string, directionID int, stopCode string) (RouteDepartures, error)
+}
@@ -0,0 +1,31 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// New builds the Provider named by name (e.g. from a --provider flag or
+// config file), defaulting to NexTrip when name is empty. Provider-specific
+// credentials (PRIM's apikey, Entur's client name, TfL's app key) are read
+// from environment variables so they don't have to be threaded through
+// every caller's flag set.
+func New(name string) (Provider, error) {
+	switch strings.ToLower(name) {
+	case "", "nextrip":
+		return NexTrip{}, nil
+	case "entur":
+		return Entur{ClientName: os.Getenv("ENTUR_CLIENT_NAME")}, nil
+	case "prim":
+		apiKey := os.Getenv("PRIM_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("provider: PRIM_API_KEY must be set to use the prim provider")
+		}
+		return PRIM{APIKey: apiKey}, nil
+	case "tfl":
+		return TfL{AppKey: os.Getenv("TFL_APP_KEY")}, nil
+	default:
+		return nil, fmt.Errorf("provider: unknown provider %q", name)
+	}
+}
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// TfL is the Provider for Transport for London's Unified API
+// (api.tfl.gov.uk), covering London's bus, tube, and rail lines.
+type TfL struct {
+	// Client is used for all upstream requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+	// AppKey is appended as the app_key query parameter, if set. TfL
+	// allows anonymous access at a much lower rate limit without one.
+	AppKey string
+}
+
+const tflBaseURL = "https://api.tfl.gov.uk"
+
+func (t TfL) client() *http.Client {
+	if t.Client != nil {
+		return t.Client
+	}
+	return http.DefaultClient
+}
+
+func (t TfL) get(ctx context.Context, url string, out interface{}) error {
+	if t.AppKey != "" {
+		url += "?app_key=" + t.AppKey
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("tfl: %s returned %s", url, resp.Status)
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+// Routes lists TfL's bus lines. routeID is a TfL line ID (e.g. "24"),
+// not a route_label, since TfL has no separate numeric label field.
+func (t TfL) Routes(ctx context.Context) ([]Route, error) {
+	var lines []struct {
+		Id   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	if err := t.get(ctx, tflBaseURL+"/Line/Mode/bus", &lines); err != nil {
+		return nil, err
+	}
+
+	routes := make([]Route, 0, len(lines))
+	for _, line := range lines {
+		routes = append(routes, Route{Route_id: line.Id, Route_label: line.Name})
+	}
+	return routes, nil
+}
+
+// Directions returns TfL's two fixed directions, "inbound" and
+// "outbound", as 0 and 1 respectively.
+func (t TfL) Directions(ctx context.Context, routeID string) ([]RouteDirection, error) {
+	return []RouteDirection{
+		{Direction_id: 0, Direction_name: "inbound"},
+		{Direction_id: 1, Direction_name: "outbound"},
+	}, nil
+}
+
+func (t TfL) Stops(ctx context.Context, routeID string, directionID int) ([]PlaceCode, error) {
+	direction := "outbound"
+	if directionID == 0 {
+		direction = "inbound"
+	}
+
+	var sequence struct {
+		Stations []struct {
+			Id         string `json:"id"`
+			CommonName string `json:"commonName"`
+		} `json:"stations"`
+	}
+
+	url := fmt.Sprintf("%s/Line/%s/Route/Sequence/%s", tflBaseURL, routeID, direction)
+	if err := t.get(ctx, url, &sequence); err != nil {
+		return nil, err
+	}
+
+	stops := make([]PlaceCode, 0, len(sequence.Stations))
+	for _, s := range sequence.Stations {
+		stops = append(stops, PlaceCode{Place_code: s.Id, Description: s.CommonName})
+	}
+	return stops, nil
+}
+
+// Departures calls TfL's Line Arrivals endpoint for a single stop,
+// ignoring directionID since TfL already scopes arrivals by stop point.
+func (t TfL) Departures(ctx context.Context, routeID string, directionID int, stopCode string) (RouteDepartures, error) {
+	var arrivals []struct {
+		ExpectedArrival string `json:"expectedArrival"`
+	}
+
+	url := fmt.Sprintf("%s/Line/%s/Arrivals/%s", tflBaseURL, routeID, stopCode)
+	if err := t.get(ctx, url, &arrivals); err != nil {
+		return RouteDepartures{}, err
+	}
+
+	departures := make([]Departure, 0, len(arrivals))
+	for _, a := range arrivals {
+		at, err := time.Parse(time.RFC3339, a.ExpectedArrival)
+		if err != nil {
+			continue
+		}
+		// TfL's Arrivals endpoint only predicts real-time positions; it
+		// has no notion of a separate static schedule.
+		departures = append(departures, Departure{Departure_time: at.Unix(), Realtime: true})
+	}
+
+	// Arrivals come back in arbitrary order; callers assume earliest first.
+	sort.Slice(departures, func(i, j int) bool { return departures[i].Departure_time < departures[j].Departure_time })
+
+	return RouteDepartures{Departures: departures}, nil
+}